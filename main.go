@@ -1,26 +1,131 @@
 package main
 
 import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
+	"regexp"
+	"runtime"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"github.com/btcsuite/btcd/btcec"
 	"github.com/fbsobreira/gotron-sdk/pkg/address"
 	"github.com/shirou/gopsutil/cpu"
+	"github.com/tyler-smith/go-bip32"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/sha3"
+	"golang.org/x/sync/semaphore"
 	_ "modernc.org/sqlite" // 使用 modernc 的 SQLite
 )
 
-// Wallet 结构体，用于存储生成的 TRON 钱包信息
+// workerMultiplier 决定固定 worker 池的大小：每个 CPU 核心对应的 worker 协程数
+const workerMultiplier = 4
+
+var (
+	flagPrefix = flag.String("prefix", "", "只保留以该前缀开头的地址（例如 TAbc）")
+	flagSuffix = flag.String("suffix", "", "只保留以该后缀结尾的地址")
+	flagRegex  = flag.String("regex", "", "使用正则表达式匹配地址，与 -prefix/-suffix 互斥")
+	flagCount  = flag.Int("count", 0, "命中 N 个地址后自动退出，0 表示不限制")
+
+	flagMode                = flag.String("mode", "wif", "密钥生成模式：wif（默认，原始私钥）或 mnemonic（BIP39 助记词 + BIP44 派生）")
+	flagWordCount           = flag.Int("words", 12, "mnemonic 模式下助记词的单词数，可选 12/15/18/21/24")
+	flagMnemonicPassphrase  = flag.String("mnemonic-passphrase", "", "mnemonic 模式下可选的 BIP39 口令（passphrase / 25th word）")
+	flagAccounts            = flag.Int("accounts", 1, "mnemonic 模式下每个助记词派生的账户数（BIP44 中的 account）")
+	flagAddressesPerAccount = flag.Int("addresses-per-account", 1, "mnemonic 模式下每个账户派生的地址数")
+
+	flagPassphrase     = flag.String("passphrase", "", "提供后，私钥将以 Keystore V3 格式加密存储，而不是明文 wif")
+	flagPassphraseFile = flag.String("passphrase-file", "", "从文件读取加密口令，优先于 -passphrase")
+	flagScryptN        = flag.Int("scrypt-n", 1<<18, "Keystore V3 scrypt KDF 的 N 参数（CPU/内存成本）")
+	flagScryptR        = flag.Int("scrypt-r", 8, "Keystore V3 scrypt KDF 的 r 参数")
+	flagScryptP        = flag.Int("scrypt-p", 1, "Keystore V3 scrypt KDF 的 p 参数")
+
+	flagStore = flag.String("store", "sqlite://./tron_wallets.db", "存储后端：sqlite://path、postgres://...、jsonl://path 或 badger://path")
+
+	flagMetricsAddr = flag.String("metrics-addr", ":9090", "Prometheus /metrics 监听地址")
+)
+
+// Matcher 判断一个 TRON 地址是否满足 vanity 搜索条件
+type Matcher func(tronAddress string) bool
+
+// tronAddressLeadingChar 是所有 TRON 主网地址的固定首字符：base58check 编码的版本字节
+// 0x41 总是产生 'T'，和 Base58 字母表里的其它位置不同，它不受后续字节影响。
+const tronAddressLeadingChar = 'T'
+
+// buildMatcher 根据命令行参数构造地址匹配器。-regex 和 -prefix/-suffix 互斥；
+// -prefix 必须以 'T' 开头，否则在固定版本字节下永远不可达。
+func buildMatcher(prefix, suffix, pattern string) (Matcher, error) {
+	if pattern != "" && (prefix != "" || suffix != "") {
+		return nil, fmt.Errorf("-regex 与 -prefix/-suffix 互斥，请只使用其中一种")
+	}
+
+	switch {
+	case pattern != "":
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("无效的正则表达式: %w", err)
+		}
+		return re.MatchString, nil
+	case prefix != "" || suffix != "":
+		if prefix != "" && rune(prefix[0]) != tronAddressLeadingChar {
+			return nil, fmt.Errorf("TRON 地址总是以 %q 开头（固定版本字节），前缀 %q 永远不可达", string(tronAddressLeadingChar), prefix)
+		}
+		return func(addr string) bool {
+			return strings.HasPrefix(addr, prefix) && strings.HasSuffix(addr, suffix)
+		}, nil
+	default:
+		return func(string) bool { return true }, nil
+	}
+}
+
+// searchSpaceCalibrationSamples 是估算搜索空间时采样生成的地址数量
+const searchSpaceCalibrationSamples = 20000
+
+// estimateSearchSpace 通过实际采样生成地址并统计命中率来估算搜索空间（期望尝试次数），
+// 而不是假设 Base58 每个字符独立同分布——TRON 地址是 base58check 编码、带固定版本字节，
+// 越靠前的字符分布偏差越大，解析公式（58^n）对 -prefix 的估算可能偏离真实值几十倍甚至更多。
+// 采样内一次都没命中时返回 0，表示无法给出可靠估算（可能极其罕见，也可能不可达）。
+func estimateSearchSpace(matcher Matcher) float64 {
+	hits := 0
+	for i := 0; i < searchSpaceCalibrationSamples; i++ {
+		_, addr := GenerateTRONKey()
+		if matcher(addr) {
+			hits++
+		}
+	}
+
+	if hits == 0 {
+		return 0
+	}
+
+	return float64(searchSpaceCalibrationSamples) / float64(hits)
+}
+
+// Wallet 结构体，用于存储生成的 TRON 钱包信息。
+// Mnemonic/DerivationPath/SeedHex 仅在 mnemonic 模式下有值，wif 模式下为空字符串。
+// 指定了加密口令时，WIF、Mnemonic、SeedHex 都会被置空，三者改为一并加密后以 KeystoreJSON 形式存储。
 type Wallet struct {
-	WIF     string
-	Address string
+	WIF            string `json:"wif,omitempty"`
+	Address        string `json:"address"`
+	Mnemonic       string `json:"mnemonic,omitempty"`
+	DerivationPath string `json:"derivation_path,omitempty"`
+	SeedHex        string `json:"seed_hex,omitempty"`
+	KeystoreJSON   string `json:"keystore_json,omitempty"`
 }
 
 // GenerateTRONKey 生成一个 TRON 私钥和地址
@@ -36,6 +141,116 @@ func GenerateTRONKey() (wif string, tronAddress string) {
 	return wif, tronAddress
 }
 
+// KeySource 生成一批待写入的钱包：wif 模式每次返回一个钱包，
+// mnemonic 模式每次返回同一个助记词派生出的全部账户/地址。
+type KeySource func() ([]Wallet, error)
+
+// newKeySource 根据 -mode 构造对应的密钥生成函数
+func newKeySource(mode string, wordCount, accounts, addressesPerAccount int, mnemonicPassphrase string) (KeySource, error) {
+	switch mode {
+	case "", "wif":
+		return func() ([]Wallet, error) {
+			wif, tronAddr := GenerateTRONKey()
+			return []Wallet{{WIF: wif, Address: tronAddr}}, nil
+		}, nil
+	case "mnemonic":
+		switch wordCount {
+		case 12, 15, 18, 21, 24:
+		default:
+			return nil, fmt.Errorf("无效的 -words: %d（必须是 12/15/18/21/24 之一）", wordCount)
+		}
+		if accounts < 1 {
+			return nil, fmt.Errorf("无效的 -accounts: %d（必须 >= 1）", accounts)
+		}
+		if addressesPerAccount < 1 {
+			return nil, fmt.Errorf("无效的 -addresses-per-account: %d（必须 >= 1）", addressesPerAccount)
+		}
+		return func() ([]Wallet, error) {
+			return GenerateHDWallets(wordCount, mnemonicPassphrase, accounts, addressesPerAccount)
+		}, nil
+	default:
+		return nil, fmt.Errorf("未知的 -mode: %s", mode)
+	}
+}
+
+// GenerateHDWallets 随机生成一个 BIP39 助记词，并从中派生出一批 TRON 地址，
+// 派生逻辑见 deriveWalletsFromMnemonic。
+func GenerateHDWallets(wordCount int, passphrase string, accounts, addressesPerAccount int) ([]Wallet, error) {
+	entropy, err := bip39.NewEntropy(wordCount * 32 / 3)
+	if err != nil {
+		return nil, fmt.Errorf("生成熵失败: %w", err)
+	}
+
+	mnemonic, err := bip39.NewMnemonic(entropy)
+	if err != nil {
+		return nil, fmt.Errorf("生成助记词失败: %w", err)
+	}
+
+	return deriveWalletsFromMnemonic(mnemonic, passphrase, accounts, addressesPerAccount)
+}
+
+// deriveWalletsFromMnemonic 从一个已知的助记词出发，按 BIP44 路径
+// m/44'/195'/account'/0/index 派生出 accounts*addressesPerAccount 个 TRON 地址
+// （195 是 TRON 在 SLIP-44 中的币种编号）。从 GenerateHDWallets 中拆出来，
+// 是为了让派生逻辑在给定固定助记词时是确定性的、可测试的。
+func deriveWalletsFromMnemonic(mnemonic, passphrase string, accounts, addressesPerAccount int) ([]Wallet, error) {
+	seed := bip39.NewSeed(mnemonic, passphrase)
+	seedHex := hex.EncodeToString(seed)
+
+	master, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return nil, fmt.Errorf("派生主密钥失败: %w", err)
+	}
+
+	wallets := make([]Wallet, 0, accounts*addressesPerAccount)
+	for account := 0; account < accounts; account++ {
+		accountKey, err := deriveHardenedPath(master, 44, 195, uint32(account))
+		if err != nil {
+			return nil, fmt.Errorf("派生账户 %d 失败: %w", account, err)
+		}
+
+		changeKey, err := accountKey.NewChildKey(0)
+		if err != nil {
+			return nil, fmt.Errorf("派生 change 节点失败: %w", err)
+		}
+
+		for i := 0; i < addressesPerAccount; i++ {
+			childKey, err := changeKey.NewChildKey(uint32(i))
+			if err != nil {
+				return nil, fmt.Errorf("派生地址 %d/%d 失败: %w", account, i, err)
+			}
+
+			pri, _ := btcec.PrivKeyFromBytes(btcec.S256(), childKey.Key)
+			tronAddr := address.PubkeyToAddress(pri.ToECDSA().PublicKey).String()
+
+			wallets = append(wallets, Wallet{
+				WIF:            hex.EncodeToString(pri.D.Bytes()),
+				Address:        tronAddr,
+				Mnemonic:       mnemonic,
+				DerivationPath: fmt.Sprintf("m/44'/195'/%d'/0/%d", account, i),
+				SeedHex:        seedHex,
+			})
+		}
+	}
+
+	return wallets, nil
+}
+
+// deriveHardenedPath 依次派生 m/purpose'/coinType'/account' 三级强化子密钥
+func deriveHardenedPath(master *bip32.Key, purpose, coinType, account uint32) (*bip32.Key, error) {
+	purposeKey, err := master.NewChildKey(bip32.FirstHardenedChild + purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	coinKey, err := purposeKey.NewChildKey(bip32.FirstHardenedChild + coinType)
+	if err != nil {
+		return nil, err
+	}
+
+	return coinKey.NewChildKey(bip32.FirstHardenedChild + account)
+}
+
 // saveWalletBatch 将生成的钱包信息批量保存到 SQLite 数据库
 func saveWalletBatch(db *sql.DB, wallets []Wallet) error {
 	tx, err := db.Begin()
@@ -44,14 +259,14 @@ func saveWalletBatch(db *sql.DB, wallets []Wallet) error {
 	}
 	defer tx.Rollback()
 
-	stmt, err := tx.Prepare("INSERT INTO tron_wallets (wif, address) VALUES (?, ?)")
+	stmt, err := tx.Prepare("INSERT INTO tron_wallets (wif, address, mnemonic, derivation_path, seed_hex, keystore_json) VALUES (?, ?, ?, ?, ?, ?)")
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
 	for _, wallet := range wallets {
-		_, err = stmt.Exec(wallet.WIF, wallet.Address)
+		_, err = stmt.Exec(wallet.WIF, wallet.Address, wallet.Mnemonic, wallet.DerivationPath, wallet.SeedHex, wallet.KeystoreJSON)
 		if err != nil {
 			return err
 		}
@@ -60,110 +275,458 @@ func saveWalletBatch(db *sql.DB, wallets []Wallet) error {
 	return tx.Commit()
 }
 
-// 动态调整生成速率，并输出当前负载信息
-func adjustRate(goroutines *int, targetLoad float64, createdWallets *int, stop chan struct{}) {
+// keystoreV3 对应 Web3 Secret Storage（以太坊 Keystore V3）的 JSON 结构
+type keystoreV3 struct {
+	Version int              `json:"version"`
+	Crypto  keystoreV3Crypto `json:"crypto"`
+}
+
+type keystoreV3Crypto struct {
+	Cipher       string               `json:"cipher"`
+	CipherText   string               `json:"ciphertext"`
+	CipherParams keystoreCipherParams `json:"cipherparams"`
+	KDF          string               `json:"kdf"`
+	KDFParams    keystoreScryptParams `json:"kdfparams"`
+	MAC          string               `json:"mac"`
+}
+
+type keystoreCipherParams struct {
+	IV string `json:"iv"`
+}
+
+type keystoreScryptParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+// walletSecret 汇总一个钱包所有足以重建私钥的敏感字段：WIF 本身，以及（mnemonic 模式下）
+// 能够反过来派生出该 WIF 的助记词和种子。三者必须作为一个整体加密，否则明文的
+// Mnemonic/SeedHex 会让旁边的 Keystore V3 密文形同虚设。
+type walletSecret struct {
+	WIF      string `json:"wif"`
+	Mnemonic string `json:"mnemonic,omitempty"`
+	SeedHex  string `json:"seed_hex,omitempty"`
+}
+
+// encryptWalletSecret 将钱包的 WIF/Mnemonic/SeedHex 一并加密为 Keystore V3 JSON，
+// 调用方随后应清空这三个明文字段，只保留返回的密文。
+func encryptWalletSecret(wallet Wallet, passphrase string, n, r, p int) (string, error) {
+	plaintext, err := json.Marshal(walletSecret{
+		WIF:      wallet.WIF,
+		Mnemonic: wallet.Mnemonic,
+		SeedHex:  wallet.SeedHex,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return EncryptSecret(string(plaintext), passphrase, n, r, p)
+}
+
+// decryptWalletSecret 解密 encryptWalletSecret 生成的 Keystore V3 JSON，还原 WIF/Mnemonic/SeedHex
+func decryptWalletSecret(keystoreJSON, passphrase string) (walletSecret, error) {
+	plaintext, err := DecryptSecret(keystoreJSON, passphrase)
+	if err != nil {
+		return walletSecret{}, err
+	}
+
+	var secret walletSecret
+	if err := json.Unmarshal([]byte(plaintext), &secret); err != nil {
+		return walletSecret{}, fmt.Errorf("解析解密后的明文失败: %w", err)
+	}
+
+	return secret, nil
+}
+
+// EncryptSecret 使用 Keystore V3 格式（scrypt KDF + AES-128-CTR + MAC）加密任意明文字符串，
+// 返回可直接写入 keystore_json 列的 JSON 文本。
+func EncryptSecret(plaintext, passphrase string, n, r, p int) (string, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("生成 salt 失败: %w", err)
+	}
+
+	derivedKey, err := scrypt.Key([]byte(passphrase), salt, n, r, p, 32)
+	if err != nil {
+		return "", fmt.Errorf("scrypt 派生密钥失败: %w", err)
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := rand.Read(iv); err != nil {
+		return "", fmt.Errorf("生成 iv 失败: %w", err)
+	}
+
+	block, err := aes.NewCipher(derivedKey[:16])
+	if err != nil {
+		return "", err
+	}
+
+	cipherText := make([]byte, len(plaintext))
+	cipher.NewCTR(block, iv).XORKeyStream(cipherText, []byte(plaintext))
+
+	mac := sha3.NewLegacyKeccak256()
+	mac.Write(derivedKey[16:32])
+	mac.Write(cipherText)
+
+	blob, err := json.Marshal(keystoreV3{
+		Version: 3,
+		Crypto: keystoreV3Crypto{
+			Cipher:       "aes-128-ctr",
+			CipherText:   hex.EncodeToString(cipherText),
+			CipherParams: keystoreCipherParams{IV: hex.EncodeToString(iv)},
+			KDF:          "scrypt",
+			KDFParams: keystoreScryptParams{
+				N: n, R: r, P: p, DKLen: 32,
+				Salt: hex.EncodeToString(salt),
+			},
+			MAC: hex.EncodeToString(mac.Sum(nil)),
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(blob), nil
+}
+
+// DecryptSecret 解密 EncryptSecret 生成的 Keystore V3 JSON，返回明文
+func DecryptSecret(keystoreJSON, passphrase string) (string, error) {
+	var ks keystoreV3
+	if err := json.Unmarshal([]byte(keystoreJSON), &ks); err != nil {
+		return "", fmt.Errorf("解析 keystore JSON 失败: %w", err)
+	}
+
+	salt, err := hex.DecodeString(ks.Crypto.KDFParams.Salt)
+	if err != nil {
+		return "", fmt.Errorf("解码 salt 失败: %w", err)
+	}
+
+	derivedKey, err := scrypt.Key([]byte(passphrase), salt, ks.Crypto.KDFParams.N, ks.Crypto.KDFParams.R, ks.Crypto.KDFParams.P, ks.Crypto.KDFParams.DKLen)
+	if err != nil {
+		return "", fmt.Errorf("scrypt 派生密钥失败: %w", err)
+	}
+
+	cipherText, err := hex.DecodeString(ks.Crypto.CipherText)
+	if err != nil {
+		return "", fmt.Errorf("解码 ciphertext 失败: %w", err)
+	}
+
+	mac := sha3.NewLegacyKeccak256()
+	mac.Write(derivedKey[16:32])
+	mac.Write(cipherText)
+	if hex.EncodeToString(mac.Sum(nil)) != ks.Crypto.MAC {
+		return "", errors.New("MAC 校验失败：口令错误或数据已损坏")
+	}
+
+	iv, err := hex.DecodeString(ks.Crypto.CipherParams.IV)
+	if err != nil {
+		return "", fmt.Errorf("解码 iv 失败: %w", err)
+	}
+
+	block, err := aes.NewCipher(derivedKey[:16])
+	if err != nil {
+		return "", err
+	}
+
+	plainText := make([]byte, len(cipherText))
+	cipher.NewCTR(block, iv).XORKeyStream(plainText, cipherText)
+
+	return string(plainText), nil
+}
+
+// resolvePassphrase 解析 -passphrase / -passphrase-file，文件优先
+func resolvePassphrase(passphrase, passphraseFile string) (string, error) {
+	if passphraseFile != "" {
+		data, err := os.ReadFile(passphraseFile)
+		if err != nil {
+			return "", fmt.Errorf("读取 -passphrase-file 失败: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	return passphrase, nil
+}
+
+// adjustRate 根据 CPU 负载动态调整 sem 的可用令牌数：
+// 负载过高时自己 Acquire 一部分令牌据为己有（收缩 worker 可用并发，持续高负载下可收缩到 0），
+// 负载过低时 Release 还给 worker 池（恢复并发），从而实现真正的弹性伸缩。
+// 同时周期性地输出当前尝试速率、命中数量，以及（在可估算搜索空间时）预计命中耗时。
+func adjustRate(ctx context.Context, sem *semaphore.Weighted, maxWeight int64, targetLoad float64, totalAttempts *int64, matchedWallets *int, searchSpace float64) {
 	ticker := time.NewTicker(5 * time.Second)
 	defer ticker.Stop()
 
+	var reserved int64     // 被本函数占用、暂不对 worker 开放的令牌数
+	var lastAttempts int64 // 上一次采样时的累计尝试次数
+
 	for {
 		select {
-		case <-stop:
+		case <-ctx.Done():
+			if reserved > 0 {
+				sem.Release(reserved)
+			}
 			return
 		case <-ticker.C:
 			load, _ := cpu.Percent(time.Second, false)
 			currentLoad := load[0]
 
-			if currentLoad < targetLoad-10 && *goroutines < 100 {
-				*goroutines++
-			} else if currentLoad > targetLoad+10 && *goroutines > 1 {
-				*goroutines--
+			if currentLoad > targetLoad+10 && reserved < maxWeight {
+				// 阻塞式 Acquire：worker 排队等待令牌时 TryAcquire 总会失败，无法真正收缩
+				if err := sem.Acquire(ctx, 1); err == nil {
+					reserved++
+				}
+			} else if currentLoad < targetLoad-10 && reserved > 0 {
+				sem.Release(1)
+				reserved--
+			}
+
+			attempts := atomic.LoadInt64(totalAttempts)
+			attemptsPerSec := float64(attempts-lastAttempts) / 5
+			lastAttempts = attempts
+
+			availableWorkers := maxWeight - reserved
+			cpuLoadPercent.Set(currentLoad)
+			workerCount.Set(float64(availableWorkers))
+
+			eta := "未知"
+			if searchSpace > 0 && attemptsPerSec > 0 {
+				eta = time.Duration(searchSpace / attemptsPerSec * float64(time.Second)).String()
 			}
 
-			fmt.Printf("当前 CPU 负载：%.2f%%, Goroutines 数量：%d, 创建的钱包数量：%d\n", currentLoad, *goroutines, *createdWallets)
+			slog.Info("rate adjusted",
+				"cpu_load_percent", currentLoad,
+				"available_workers", availableWorkers,
+				"max_workers", maxWeight,
+				"attempts_per_sec", attemptsPerSec,
+				"matched_wallets", *matchedWallets,
+				"eta_to_match", eta,
+			)
 		}
 	}
 }
 
 func main() {
-	db, err := sql.Open("sqlite", "./tron_wallets.db")
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, nil)))
+
+	if len(os.Args) > 1 && os.Args[1] == "decrypt" {
+		runDecrypt(os.Args[2:])
+		return
+	}
+
+	runGenerate()
+}
+
+// runDecrypt 实现 `decrypt` 子命令：读取数据库中的 keystore_json，解密后打印明文 wif
+func runDecrypt(args []string) {
+	fs := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	dbPath := fs.String("db", "./tron_wallets.db", "SQLite 数据库路径")
+	passphrase := fs.String("passphrase", "", "解密口令")
+	passphraseFile := fs.String("passphrase-file", "", "从文件读取解密口令，优先于 -passphrase")
+	fs.Parse(args)
+
+	pass, err := resolvePassphrase(*passphrase, *passphraseFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if pass == "" {
+		log.Fatal("decrypt 子命令需要通过 -passphrase 或 -passphrase-file 提供口令")
+	}
+
+	db, err := sql.Open("sqlite", *dbPath)
 	if err != nil {
 		log.Fatal("无法连接到数据库:", err)
 	}
 	defer db.Close()
 
-	_, err = db.Exec(`
-        CREATE TABLE IF NOT EXISTS tron_wallets (
-            id INTEGER PRIMARY KEY AUTOINCREMENT,
-            wif TEXT NOT NULL,
-            address TEXT NOT NULL
-        );
-    `)
+	rows, err := db.Query("SELECT id, address, keystore_json FROM tron_wallets WHERE keystore_json IS NOT NULL AND keystore_json != ''")
 	if err != nil {
-		log.Fatal("无法创建表:", err)
+		log.Fatal("查询失败:", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int64
+		var addr, ksJSON string
+		if err := rows.Scan(&id, &addr, &ksJSON); err != nil {
+			log.Fatal(err)
+		}
+
+		secret, err := decryptWalletSecret(ksJSON, pass)
+		if err != nil {
+			fmt.Printf("id=%d address=%s 解密失败: %v\n", id, addr, err)
+			continue
+		}
+
+		if secret.Mnemonic == "" {
+			fmt.Printf("id=%d address=%s wif=%s\n", id, addr, secret.WIF)
+		} else {
+			fmt.Printf("id=%d address=%s wif=%s mnemonic=%q seed_hex=%s\n", id, addr, secret.WIF, secret.Mnemonic, secret.SeedHex)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func runGenerate() {
+	flag.Parse()
+
+	matcher, err := buildMatcher(*flagPrefix, *flagSuffix, *flagRegex)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var searchSpace float64
+	if *flagPrefix != "" || *flagSuffix != "" || *flagRegex != "" {
+		// 通过采样校准搜索空间，而不是假设 Base58 每个字符独立同分布
+		// （TRON 地址的固定版本字节会让靠前的字符明显偏态）。
+		searchSpace = estimateSearchSpace(matcher)
+		if searchSpace > 0 {
+			fmt.Printf("搜索空间约为 %.0f 个地址（基于 %d 次采样估算）\n", searchSpace, searchSpaceCalibrationSamples)
+		} else {
+			fmt.Printf("未能在 %d 次采样中命中任何地址，无法估算搜索空间/ETA，将继续搜索\n", searchSpaceCalibrationSamples)
+		}
+	}
+
+	store, err := newStore(*flagStore)
+	if err != nil {
+		log.Fatal("无法初始化存储后端:", err)
+	}
+	defer store.Close()
+
+	keySource, err := newKeySource(*flagMode, *flagWordCount, *flagAccounts, *flagAddressesPerAccount, *flagMnemonicPassphrase)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	passphrase, err := resolvePassphrase(*flagPassphrase, *flagPassphraseFile)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	goroutines := 5    // 初始 Goroutine 数量
 	targetLoad := 70.0 // 目标 CPU 使用率
-	createdWallets := 0
-	stop := make(chan struct{})
+	matchedWallets := 0
+	var totalAttempts int64
 	walletChannel := make(chan Wallet, 100)
 
-	// 处理退出信号
+	maxWeight := int64(runtime.NumCPU() * workerMultiplier) // 固定 worker 池大小
+	sem := semaphore.NewWeighted(maxWeight)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 处理退出信号：只负责取消 ctx，不触碰 channel 的生命周期
 	signalChannel := make(chan os.Signal, 1)
 	signal.Notify(signalChannel, os.Interrupt, syscall.SIGTERM)
 
 	go func() {
 		<-signalChannel
-		fmt.Println("\n收到退出信号，正在退出...")
-		close(stop)
-		close(walletChannel)
+		slog.Info("收到退出信号，正在退出...")
+		cancel()
 	}()
 
+	serveMetrics(*flagMetricsAddr)
+
 	var wg sync.WaitGroup
-	go adjustRate(&goroutines, targetLoad, &createdWallets, stop)
+	go adjustRate(ctx, sem, maxWeight, targetLoad, &totalAttempts, &matchedWallets, searchSpace)
 
-	// 动态启动 Goroutines 生成钱包
-	for i := 0; i < goroutines; i++ {
+	// 固定数量的 worker：每次生成前先获取一个信号量令牌，只有命中 matcher 的地址才会被推送。
+	// keySource 在 wif 模式下每次产出一个钱包，mnemonic 模式下每次产出一批同源派生钱包。
+	for i := int64(0); i < maxWeight; i++ {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
 			for {
-				select {
-				case <-stop:
+				if err := sem.Acquire(ctx, 1); err != nil {
+					// ctx 已取消
 					return
-				default:
-					tronWif, tronAddr := GenerateTRONKey()
-					walletChannel <- Wallet{WIF: tronWif, Address: tronAddr}
 				}
+
+				keygenStart := time.Now()
+				generated, err := keySource()
+				keygenDurationSeconds.Observe(time.Since(keygenStart).Seconds())
+				if err != nil {
+					sem.Release(1)
+					slog.Error("生成密钥失败", "err", err)
+					continue
+				}
+				atomic.AddInt64(&totalAttempts, int64(len(generated)))
+				walletsGeneratedTotal.Add(float64(len(generated)))
+
+				for _, w := range generated {
+					if !matcher(w.Address) {
+						continue
+					}
+
+					if passphrase != "" {
+						ksJSON, err := encryptWalletSecret(w, passphrase, *flagScryptN, *flagScryptR, *flagScryptP)
+						if err != nil {
+							slog.Error("加密私钥失败", "err", err)
+							continue
+						}
+						// Mnemonic/SeedHex 能反过来派生出 WIF，必须和 WIF 一起从明文列中清空
+						w.WIF = ""
+						w.Mnemonic = ""
+						w.SeedHex = ""
+						w.KeystoreJSON = ksJSON
+					}
+
+					select {
+					case <-ctx.Done():
+						sem.Release(1)
+						return
+					case walletChannel <- w:
+					}
+				}
+
+				sem.Release(1)
 			}
 		}()
 	}
 
+	// 只有生产者的 wg-waiter 负责关闭 walletChannel，避免消费者侧重复 close
 	go func() {
 		wg.Wait()
 		close(walletChannel)
 	}()
 
-	// 监听 walletChannel 并批量保存钱包
+	// flushBatch 统一负责计时、更新指标并在失败时终止进程
+	flushBatch := func(wallets []Wallet) {
+		start := time.Now()
+		err := store.SaveBatch(wallets)
+		dbBatchFlushSeconds.Observe(time.Since(start).Seconds())
+
+		if err != nil {
+			dbBatchErrorsTotal.Inc()
+			log.Fatal("无法保存钱包信息:", err)
+		}
+		walletsPersistedTotal.Add(float64(len(wallets)))
+	}
+
+	// 监听 walletChannel 并批量保存钱包，channel 关闭前会把剩余的钱包全部消费完
 	var wallets []Wallet
 	for wallet := range walletChannel {
 		wallets = append(wallets, wallet)
-		createdWallets++
+		matchedWallets++
+		channelQueueDepth.Set(float64(len(walletChannel)))
+
+		if *flagCount > 0 && matchedWallets >= *flagCount {
+			cancel() // 达到 -count 指定的命中数量，通知所有 worker 退出
+		}
 
 		if len(wallets) >= 100 {
-			if err := saveWalletBatch(db, wallets); err != nil {
-				log.Fatal("无法保存钱包信息到数据库:", err)
-			}
+			flushBatch(wallets)
 			wallets = wallets[:0] // 清空切片
 		}
 	}
 
-	// 最后批量保存
+	// 最后批量保存，确保不丢失最后一批未满的数据
 	if len(wallets) > 0 {
-		if err := saveWalletBatch(db, wallets); err != nil {
-			log.Fatal("无法保存钱包信息到数据库:", err)
-		}
+		flushBatch(wallets)
 	}
 
-	fmt.Println("程序已安全退出。")
+	slog.Info("程序已安全退出。")
 }
@@ -0,0 +1,112 @@
+package main
+
+import "testing"
+
+func TestEncryptDecryptWalletSecretRoundTrip(t *testing.T) {
+	wallet := Wallet{
+		WIF:      "deadbeef",
+		Address:  "TXYZtest",
+		Mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		SeedHex:  "0123456789abcdef",
+	}
+
+	ksJSON, err := encryptWalletSecret(wallet, "correct-horse", 1<<12, 8, 1)
+	if err != nil {
+		t.Fatalf("encryptWalletSecret failed: %v", err)
+	}
+
+	secret, err := decryptWalletSecret(ksJSON, "correct-horse")
+	if err != nil {
+		t.Fatalf("decryptWalletSecret failed: %v", err)
+	}
+
+	if secret.WIF != wallet.WIF {
+		t.Errorf("WIF = %q, want %q", secret.WIF, wallet.WIF)
+	}
+	if secret.Mnemonic != wallet.Mnemonic {
+		t.Errorf("Mnemonic = %q, want %q", secret.Mnemonic, wallet.Mnemonic)
+	}
+	if secret.SeedHex != wallet.SeedHex {
+		t.Errorf("SeedHex = %q, want %q", secret.SeedHex, wallet.SeedHex)
+	}
+}
+
+func TestDecryptWalletSecretWrongPassphrase(t *testing.T) {
+	wallet := Wallet{WIF: "deadbeef", Address: "TXYZtest"}
+
+	ksJSON, err := encryptWalletSecret(wallet, "correct-horse", 1<<12, 8, 1)
+	if err != nil {
+		t.Fatalf("encryptWalletSecret failed: %v", err)
+	}
+
+	if _, err := decryptWalletSecret(ksJSON, "wrong-passphrase"); err == nil {
+		t.Fatal("decryptWalletSecret with wrong passphrase should fail, got nil error")
+	}
+}
+
+func TestDeriveWalletsFromMnemonicIsDeterministic(t *testing.T) {
+	const mnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	first, err := deriveWalletsFromMnemonic(mnemonic, "", 2, 3)
+	if err != nil {
+		t.Fatalf("deriveWalletsFromMnemonic failed: %v", err)
+	}
+
+	second, err := deriveWalletsFromMnemonic(mnemonic, "", 2, 3)
+	if err != nil {
+		t.Fatalf("deriveWalletsFromMnemonic failed: %v", err)
+	}
+
+	if len(first) != len(second) || len(first) != 6 {
+		t.Fatalf("got %d/%d wallets, want 6/6", len(first), len(second))
+	}
+
+	for i := range first {
+		if first[i].WIF != second[i].WIF || first[i].Address != second[i].Address {
+			t.Errorf("wallet %d not deterministic: %+v vs %+v", i, first[i], second[i])
+		}
+		if first[i].DerivationPath != second[i].DerivationPath {
+			t.Errorf("wallet %d derivation path not deterministic: %q vs %q", i, first[i].DerivationPath, second[i].DerivationPath)
+		}
+	}
+
+	differentPassphrase, err := deriveWalletsFromMnemonic(mnemonic, "a-different-passphrase", 1, 1)
+	if err != nil {
+		t.Fatalf("deriveWalletsFromMnemonic failed: %v", err)
+	}
+	if differentPassphrase[0].WIF == first[0].WIF {
+		t.Error("different BIP39 passphrase should derive a different WIF from the same mnemonic")
+	}
+}
+
+func TestBuildMatcherPrefixSuffix(t *testing.T) {
+	matcher, err := buildMatcher("TAbc", "9Z", "")
+	if err != nil {
+		t.Fatalf("buildMatcher failed: %v", err)
+	}
+
+	if !matcher("TAbc123456789Z") {
+		t.Error("expected address with matching prefix and suffix to match")
+	}
+	if matcher("TAbc1234567890") {
+		t.Error("expected address without matching suffix to not match")
+	}
+	if matcher("TXyz123456789Z") {
+		t.Error("expected address without matching prefix to not match")
+	}
+}
+
+func TestBuildMatcherRejectsUnreachablePrefix(t *testing.T) {
+	if _, err := buildMatcher("X", "", ""); err == nil {
+		t.Fatal("expected buildMatcher to reject a prefix not starting with 'T'")
+	}
+}
+
+func TestBuildMatcherRejectsRegexWithPrefixOrSuffix(t *testing.T) {
+	if _, err := buildMatcher("T", "", "^T"); err == nil {
+		t.Fatal("expected buildMatcher to reject -regex combined with -prefix/-suffix")
+	}
+	if _, err := buildMatcher("", "abc", "^T"); err == nil {
+		t.Fatal("expected buildMatcher to reject -regex combined with -prefix/-suffix")
+	}
+}
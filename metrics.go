@@ -0,0 +1,66 @@
+package main
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	walletsGeneratedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "wallets_generated_total",
+		Help: "尝试生成的钱包总数（含未命中 matcher 而被丢弃的）",
+	})
+
+	walletsPersistedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "wallets_persisted_total",
+		Help: "成功持久化的钱包总数",
+	})
+
+	dbBatchErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "db_batch_errors_total",
+		Help: "批量保存失败的次数",
+	})
+
+	keygenDurationSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "keygen_duration_seconds",
+		Help:    "单次密钥生成耗时（wif 模式对应一个地址，mnemonic 模式对应一批派生地址）",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	dbBatchFlushSeconds = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "db_batch_flush_seconds",
+		Help:    "单次批量保存耗时",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	workerCount = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "worker_count",
+		Help: "当前对 worker 开放的并发数（受 sem 的可用令牌数限制）",
+	})
+
+	cpuLoadPercent = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "cpu_load_percent",
+		Help: "最近一次采样到的 CPU 负载百分比",
+	})
+
+	channelQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "channel_queue_depth",
+		Help: "walletChannel 中尚未落盘的钱包数量",
+	})
+)
+
+// serveMetrics 在后台启动一个 /metrics 端点供 Prometheus 抓取，监听失败只记录日志、不终止主流程
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	go func() {
+		if err := http.ListenAndServe(addr, mux); err != nil {
+			slog.Error("metrics 服务退出", "err", err)
+		}
+	}()
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+
+	"github.com/dgraph-io/badger/v4"
+	_ "github.com/lib/pq"
+)
+
+// Store 是钱包持久化后端的统一接口，所有实现都必须支持批量写入和优雅关闭
+type Store interface {
+	SaveBatch(wallets []Wallet) error
+	Close() error
+}
+
+// newStore 根据 -store 指定的 URI 构造对应的存储后端。
+// 支持的 scheme：sqlite://path（默认）、postgres://...、jsonl://path、badger://path。
+func newStore(uri string) (Store, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("解析 -store 失败: %w", err)
+	}
+
+	switch parsed.Scheme {
+	case "", "sqlite":
+		return newSQLiteStore(storePath(parsed))
+	case "postgres", "postgresql":
+		return newPostgresStore(uri)
+	case "jsonl":
+		return newJSONLStore(storePath(parsed))
+	case "badger":
+		return newBadgerStore(storePath(parsed))
+	default:
+		return nil, fmt.Errorf("未知的 -store scheme: %s", parsed.Scheme)
+	}
+}
+
+// storePath 从 URI 中取出本地文件路径部分，兼容 "sqlite://./a.db" 和 "jsonl:./a.jsonl" 两种写法
+func storePath(u *url.URL) string {
+	if u.Opaque != "" {
+		return u.Opaque
+	}
+	return u.Host + u.Path
+}
+
+// sqliteStore 是基于 modernc.org/sqlite 的默认存储后端
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func newSQLiteStore(path string) (*sqliteStore, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("无法连接到数据库: %w", err)
+	}
+
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS tron_wallets (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            wif TEXT NOT NULL,
+            address TEXT NOT NULL,
+            mnemonic TEXT,
+            derivation_path TEXT,
+            seed_hex TEXT,
+            keystore_json TEXT
+        );
+    `)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("无法创建表: %w", err)
+	}
+
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) SaveBatch(wallets []Wallet) error {
+	return saveWalletBatch(s.db, wallets)
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+// postgresStore 通过 database/sql + lib/pq 写入 Postgres
+type postgresStore struct {
+	db *sql.DB
+}
+
+func newPostgresStore(connStr string) (*postgresStore, error) {
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, fmt.Errorf("无法连接到 postgres: %w", err)
+	}
+
+	_, err = db.Exec(`
+        CREATE TABLE IF NOT EXISTS tron_wallets (
+            id SERIAL PRIMARY KEY,
+            wif TEXT NOT NULL,
+            address TEXT NOT NULL,
+            mnemonic TEXT,
+            derivation_path TEXT,
+            seed_hex TEXT,
+            keystore_json TEXT
+        );
+    `)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("无法创建表: %w", err)
+	}
+
+	return &postgresStore{db: db}, nil
+}
+
+func (s *postgresStore) SaveBatch(wallets []Wallet) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT INTO tron_wallets (wif, address, mnemonic, derivation_path, seed_hex, keystore_json) VALUES ($1, $2, $3, $4, $5, $6)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, wallet := range wallets {
+		_, err = stmt.Exec(wallet.WIF, wallet.Address, wallet.Mnemonic, wallet.DerivationPath, wallet.SeedHex, wallet.KeystoreJSON)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *postgresStore) Close() error {
+	return s.db.Close()
+}
+
+// jsonlStore 将每个钱包作为一行 JSON 追加写入文件，便于接入其他流水线
+type jsonlStore struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newJSONLStore(path string) (*jsonlStore, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("无法打开 jsonl 文件: %w", err)
+	}
+
+	return &jsonlStore{file: file}, nil
+}
+
+func (s *jsonlStore) SaveBatch(wallets []Wallet) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, wallet := range wallets {
+		line, err := json.Marshal(wallet)
+		if err != nil {
+			return err
+		}
+
+		if _, err := s.file.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *jsonlStore) Close() error {
+	return s.file.Close()
+}
+
+// badgerStore 是以地址为键的嵌入式 KV 存储，支持 O(1) 的按地址查找
+type badgerStore struct {
+	db *badger.DB
+}
+
+func newBadgerStore(path string) (*badgerStore, error) {
+	db, err := badger.Open(badger.DefaultOptions(path))
+	if err != nil {
+		return nil, fmt.Errorf("无法打开 badger 数据库: %w", err)
+	}
+
+	return &badgerStore{db: db}, nil
+}
+
+func (s *badgerStore) SaveBatch(wallets []Wallet) error {
+	return s.db.Update(func(txn *badger.Txn) error {
+		for _, wallet := range wallets {
+			value, err := json.Marshal(wallet)
+			if err != nil {
+				return err
+			}
+			if err := txn.Set([]byte(wallet.Address), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *badgerStore) Close() error {
+	return s.db.Close()
+}